@@ -1,5 +1,54 @@
-//! Let cargo know that we need to recompile when the migrations change.
+//! Let cargo know that we need to recompile when the migrations change, and
+//! bake build metadata into the binary.
+use std::{env, path::Path, process::Command};
+
 fn main() {
     // trigger recompilation when a new migration is added
     println!("cargo:rerun-if-changed=migrations");
+
+    // record what `--version` was built from. Git knows this for any build
+    // from a checkout; the environment variables cover builds without one.
+    watch_git();
+    let commit = metadata_var("BEEPS_GIT_SHA").or_else(|| git(&["rev-parse", "HEAD"]));
+    let date = metadata_var("BEEPS_BUILD_DATE").or_else(|| git(&["log", "-1", "--format=%cI"]));
+
+    let info = match (commit, date) {
+        (Some(commit), Some(date)) => format!(" ({commit} {date})"),
+        (Some(only), None) | (None, Some(only)) => format!(" ({only})"),
+        (None, None) => String::new(),
+    };
+    println!("cargo:rustc-env=BEEPS_BUILD_INFO={info}");
+}
+
+/// Read a build metadata variable, treating an empty value as unset.
+fn metadata_var(name: &str) -> Option<String> {
+    println!("cargo:rerun-if-env-changed={name}");
+
+    env::var(name).ok().filter(|value| !value.is_empty())
+}
+
+/// Rerun when the checked-out commit changes.
+fn watch_git() {
+    // only watch paths that exist, since cargo reruns the build script every
+    // time for missing ones (e.g. when building from a source tarball)
+    for path in ["../.git/HEAD", "../.git/refs"] {
+        if Path::new(path).exists() {
+            println!("cargo:rerun-if-changed={path}");
+        }
+    }
+}
+
+/// Run a git command and get its trimmed output, if we're building from a
+/// git checkout.
+fn git(args: &[&str]) -> Option<String> {
+    let output = Command::new("git").args(args).output().ok()?;
+
+    if !output.status.success() {
+        return None;
+    }
+
+    String::from_utf8(output.stdout)
+        .ok()
+        .map(|out| out.trim().to_string())
+        .filter(|out| !out.is_empty())
 }
@@ -31,9 +31,13 @@ use tower_http::{compression, decompression, limit, sensitive_headers, timeout,
 use tracing::level_filters::LevelFilter;
 use tracing_subscriber::{fmt, layer::SubscriberExt, util::SubscriberInitExt, EnvFilter};
 
+/// Version reported by `--version`, including the commit and date the binary
+/// was built from when known (see `build.rs`.)
+const VERSION: &str = concat!(env!("CARGO_PKG_VERSION"), env!("BEEPS_BUILD_INFO"));
+
 /// Configuration for the server
 #[derive(Debug, Parser)]
-#[clap(version)]
+#[clap(version = VERSION)]
 struct Config {
     /// On what interface and port to listen
     #[clap(long, env, default_value = "127.0.0.1:3000")]
@@ -136,3 +140,29 @@ async fn main() {
 
     axum::serve(listener, app).await.unwrap();
 }
+
+#[cfg(test)]
+mod test {
+    use super::*;
+    use clap::CommandFactory;
+
+    #[test]
+    fn version_includes_crate_version() {
+        let version = Config::command().render_version();
+
+        assert!(version.contains(env!("CARGO_PKG_VERSION")));
+    }
+
+    #[test]
+    #[ignore = "needs BEEPS_GIT_SHA; CI runs it with --include-ignored"]
+    fn version_includes_commit() {
+        let commit = std::env::var("BEEPS_GIT_SHA").expect("BEEPS_GIT_SHA should be set");
+
+        let version = Config::command().render_version();
+
+        assert!(
+            version.contains(&commit),
+            "{version} should contain {commit}"
+        );
+    }
+}